@@ -0,0 +1,138 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PlatformCertificateURL 是下载微信支付平台证书列表的接口地址.
+const PlatformCertificateURL = "https://api.mch.weixin.qq.com/v3/certificates"
+
+// CertificateStore 维护一份微信支付平台证书, 以 serial_no 为 key, 支持并发安全的读取和整体替换.
+type CertificateStore struct {
+	rwmutex sync.RWMutex
+	certs   map[string]*x509.Certificate // serial_no => certificate
+}
+
+// NewCertificateStore 创建一个空的证书仓库, 需要调用 ReplaceAll 或者 Client 的自动刷新机制来填充证书.
+func NewCertificateStore() *CertificateStore {
+	return &CertificateStore{
+		certs: make(map[string]*x509.Certificate),
+	}
+}
+
+// Certificate 按照 serial_no 查找证书, 不存在时返回 nil.
+func (store *CertificateStore) Certificate(serialNo string) *x509.Certificate {
+	store.rwmutex.RLock()
+	cert := store.certs[serialNo]
+	store.rwmutex.RUnlock()
+	return cert
+}
+
+// ReplaceAll 整体替换证书仓库的内容.
+func (store *CertificateStore) ReplaceAll(certs map[string]*x509.Certificate) {
+	store.rwmutex.Lock()
+	store.certs = certs
+	store.rwmutex.Unlock()
+}
+
+// Empty 返回证书仓库当前是否为空.
+func (store *CertificateStore) Empty() bool {
+	store.rwmutex.RLock()
+	empty := len(store.certs) == 0
+	store.rwmutex.RUnlock()
+	return empty
+}
+
+// platformCertificateListResponse 对应 GET /v3/certificates 的应答 body.
+type platformCertificateListResponse struct {
+	Data []struct {
+		SerialNo           string `json:"serial_no"`
+		EffectiveTime      string `json:"effective_time"`
+		ExpireTime         string `json:"expire_time"`
+		EncryptCertificate struct {
+			Algorithm      string `json:"algorithm"`
+			Nonce          string `json:"nonce"`
+			AssociatedData string `json:"associated_data"`
+			Ciphertext     string `json:"ciphertext"`
+		} `json:"encrypt_certificate"`
+	} `json:"data"`
+}
+
+// fetchCertificates 下载并用 apiv3Key 解密最新的平台证书列表.
+//  httpClient 在首次下载证书时(信任首次下载)是只做 Credential 签名、不验证应答签名的 *http.Client,
+//  仓库里已有证书之后则是会验证应答签名的 *http.Client, see startAutoRefresh.
+func fetchCertificates(httpClient *http.Client, apiv3Key string) (map[string]*x509.Certificate, error) {
+	resp, err := httpClient.Get(PlatformCertificateURL)
+	if err != nil {
+		return nil, fmt.Errorf("apiv3: 下载平台证书失败: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("apiv3: 读取平台证书应答失败: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("apiv3: 下载平台证书失败, http status: %d, body: %s", resp.StatusCode, body)
+	}
+
+	var result platformCertificateListResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("apiv3: 解析平台证书应答失败: %s", err)
+	}
+
+	certs := make(map[string]*x509.Certificate, len(result.Data))
+	for _, item := range result.Data {
+		plaintext, err := DecryptAESGCM(apiv3Key, item.EncryptCertificate.Nonce,
+			item.EncryptCertificate.AssociatedData, item.EncryptCertificate.Ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("apiv3: 解密平台证书(serial_no=%q)失败: %s", item.SerialNo, err)
+		}
+
+		cert, err := parseCertificatePEM(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("apiv3: 解析平台证书(serial_no=%q)失败: %s", item.SerialNo, err)
+		}
+		certs[item.SerialNo] = cert
+	}
+	return certs, nil
+}
+
+// startAutoRefresh 启动一个后台 goroutine, 每隔 interval 时间刷新一次证书仓库, 直到 stop channel 被关闭.
+//  bootstrapClient:  不验证应答签名, 仅在仓库仍为空(还没有任何可信证书)时使用
+//  validatingClient: 会用仓库里已有的证书验证应答签名, 仓库不为空时优先使用这个
+func startAutoRefresh(store *CertificateStore, bootstrapClient, validatingClient *http.Client, apiv3Key string, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				httpClient := validatingClient
+				if store.Empty() {
+					httpClient = bootstrapClient
+				}
+
+				certs, err := fetchCertificates(httpClient, apiv3Key)
+				if err != nil {
+					// 刷新失败不影响当前仓库里已有的证书继续使用, 等待下一次刷新重试.
+					continue
+				}
+				store.ReplaceAll(certs)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,144 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// DefaultCertificateRefreshInterval 是 Client 自动刷新平台证书的默认周期.
+const DefaultCertificateRefreshInterval = 1 * time.Hour
+
+// Client 实现了 http.RoundTripper, 对发出的请求自动附加 Authorization 头, 对收到的应答自动验签,
+// 并且在后台定时刷新微信支付平台证书.
+type Client struct {
+	mchId      string
+	apiv3Key   string
+	credential Credential
+	certs      *CertificateStore
+	validator  Validator
+	transport  http.RoundTripper
+
+	stop chan struct{}
+}
+
+// NewClient 创建一个 Client.
+//  mchId:      商户号
+//  serialNo:   商户 API 证书序列号
+//  privateKey: 商户 API 证书的 PKCS#8 私钥, see LoadPrivateKeyWithPath
+//  apiv3Key:   APIv3 密钥, 用于解密平台证书和回调通知
+//
+// 返回的 Client 本身实现了 http.RoundTripper, 调用 HTTPClient 得到一个可以直接用于发起请求的 *http.Client;
+// Close 用于停止后台的证书自动刷新.
+func NewClient(mchId, serialNo string, privateKey *rsa.PrivateKey, apiv3Key string) *Client {
+	credential := NewCredential(mchId, serialNo, privateKey)
+	certs := NewCertificateStore()
+
+	c := &Client{
+		mchId:      mchId,
+		apiv3Key:   apiv3Key,
+		credential: credential,
+		certs:      certs,
+		validator:  NewValidator(certs),
+		transport:  http.DefaultTransport,
+		stop:       make(chan struct{}),
+	}
+
+	// 首次同步拉取一次证书(信任首次下载, 此时尚无证书可供验签), 再开启后台定时刷新.
+	bootstrapClient := &http.Client{
+		Transport: &signingTransport{credential: credential, transport: http.DefaultTransport},
+	}
+	if certsMap, err := fetchCertificates(bootstrapClient, apiv3Key); err == nil {
+		certs.ReplaceAll(certsMap)
+	}
+
+	// 一旦仓库里有了证书, 后续的定时刷新改用 c 本身(会验证应答签名), 不再信任未验签的应答.
+	validatingClient := c.HTTPClient()
+	startAutoRefresh(certs, bootstrapClient, validatingClient, apiv3Key, DefaultCertificateRefreshInterval, c.stop)
+
+	return c
+}
+
+// Close 停止证书的后台自动刷新.
+func (c *Client) Close() {
+	close(c.stop)
+}
+
+// HTTPClient 返回一个使用该 Client 进行请求签名和应答验签的 *http.Client.
+func (c *Client) HTTPClient() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// RoundTrip 实现 http.RoundTripper, 对请求签名, 对应答验签.
+func (c *Client) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	urlPath := req.URL.Path
+	if req.URL.RawQuery != "" {
+		urlPath += "?" + req.URL.RawQuery
+	}
+
+	authorization, err := c.credential.Authorization(req.Method, urlPath, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 微信支付对 2xx 的正常应答和非 2xx 的业务错误应答都会签名(都带有 Wechatpay-Signature 等头部),
+	// 只要带了签名头就要验签, 不能因为状态码不是 2xx 就跳过, 否则伪造的错误应答也会被直接放行给调用方.
+	if signature := resp.Header.Get("Wechatpay-Signature"); signature != "" {
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		serialNo := resp.Header.Get("Wechatpay-Serial")
+		timestamp := resp.Header.Get("Wechatpay-Timestamp")
+		nonceStr := resp.Header.Get("Wechatpay-Nonce")
+		if err := c.validator.Validate(serialNo, timestamp, nonceStr, respBody, signature); err != nil {
+			return nil, fmt.Errorf("apiv3: 验证应答签名失败: %s", err)
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	}
+	return resp, nil
+}
+
+// signingTransport 只负责签名请求, 不验证应答, 用于首次下载平台证书(此时尚无证书可供验签).
+type signingTransport struct {
+	credential Credential
+	transport  http.RoundTripper
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization, err := t.credential.Authorization(req.Method, req.URL.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+	return t.transport.RoundTrip(req)
+}
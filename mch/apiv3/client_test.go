@@ -0,0 +1,124 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newSignedTestServer 启动一个用 priv 对应私钥对应答签名(伪装成微信支付平台)的测试服务器.
+func newSignedTestServer(t *testing.T, priv *rsa.PrivateKey, serialNo string, statusCode int, respBody []byte, tamper bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		const (
+			timestamp = "1600000000"
+			nonceStr  = "respnonce"
+		)
+
+		message := timestamp + "\n" + nonceStr + "\n" + string(respBody) + "\n"
+		hashed := sha256.Sum256([]byte(message))
+		sigBytes, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, hashed[:])
+		if err != nil {
+			t.Fatalf("rsa.SignPKCS1v15: %v", err)
+		}
+		signature := base64.StdEncoding.EncodeToString(sigBytes)
+		if tamper {
+			signature = signature[:len(signature)-4] + "abcd"
+		}
+
+		w.Header().Set("Wechatpay-Serial", serialNo)
+		w.Header().Set("Wechatpay-Timestamp", timestamp)
+		w.Header().Set("Wechatpay-Nonce", nonceStr)
+		w.Header().Set("Wechatpay-Signature", signature)
+		w.WriteHeader(statusCode)
+		w.Write(respBody)
+	}))
+}
+
+func newTestClient(t *testing.T, platformSerialNo string, platformCert *x509.Certificate) *Client {
+	t.Helper()
+
+	merchantKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	certs := NewCertificateStore()
+	certs.ReplaceAll(map[string]*x509.Certificate{platformSerialNo: platformCert})
+
+	return &Client{
+		mchId:      "mchid",
+		apiv3Key:   "0123456789abcdef0123456789abcdef",
+		credential: NewCredential("mchid", "merchant-serial", merchantKey),
+		certs:      certs,
+		validator:  NewValidator(certs),
+		transport:  http.DefaultTransport,
+		stop:       make(chan struct{}),
+	}
+}
+
+func TestClient_RoundTrip_ValidatesSignedNon2xxResponse(t *testing.T) {
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	platformCert := selfSignedCert(t, platformKey)
+	c := newTestClient(t, "platform-serial", platformCert)
+
+	respBody := []byte(`{"code":"RESOURCE_NOT_EXISTS","message":"订单不存在"}`)
+	server := newSignedTestServer(t, platformKey, "platform-serial", http.StatusNotFound, respBody, false)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+	resp, err := c.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	gotBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(gotBody, respBody) {
+		t.Fatalf("body = %q, want %q", gotBody, respBody)
+	}
+}
+
+func TestClient_RoundTrip_RejectsTamperedNon2xxResponse(t *testing.T) {
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	platformCert := selfSignedCert(t, platformKey)
+	c := newTestClient(t, "platform-serial", platformCert)
+
+	respBody := []byte(`{"code":"RESOURCE_NOT_EXISTS","message":"订单不存在"}`)
+	server := newSignedTestServer(t, platformKey, "platform-serial", http.StatusNotFound, respBody, true)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+	if _, err := c.RoundTrip(req); err == nil {
+		t.Fatal("expected error for tampered signature on non-2xx response, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+// Package apiv3 实现微信支付 APIv3 的请求签名和应答验签方案, 参见
+//  https://pay.weixin.qq.com/wiki/doc/apiv3/wechatpay/wechatpay4_0.shtml
+package apiv3
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Credential 用于生成请求的 Authorization 头.
+type Credential interface {
+	// Authorization 按照 APIv3 的规则对一次请求签名, 返回完整的 Authorization 头部的值.
+	//  method:  HTTP 方法, 大写, 如 "GET", "POST"
+	//  urlPath: 请求 url 中 除去 scheme, host 之外的部分, 包含 query, 如 "/v3/pay/transactions/native"
+	//  body:    请求的 body, GET 请求传 nil 即可
+	Authorization(method, urlPath string, body []byte) (string, error)
+}
+
+// rsaCredential 是 Credential 基于商户 API 证书(RSA)私钥的默认实现.
+type rsaCredential struct {
+	mchId      string
+	serialNo   string
+	privateKey *rsa.PrivateKey
+}
+
+// NewCredential 创建一个基于商户私钥的 Credential.
+//  mchId:      商户号
+//  serialNo:   商户 API 证书序列号
+//  privateKey: 商户 API 证书的 PKCS#8 私钥, see LoadPrivateKeyWithPath
+func NewCredential(mchId, serialNo string, privateKey *rsa.PrivateKey) Credential {
+	return &rsaCredential{
+		mchId:      mchId,
+		serialNo:   serialNo,
+		privateKey: privateKey,
+	}
+}
+
+func (c *rsaCredential) Authorization(method, urlPath string, body []byte) (string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	nonceStr, err := randomNonceStr(32)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := c.sign(method, urlPath, timestamp, nonceStr, body)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`WECHATPAY2-SHA256-RSA2048 mchid="%s",nonce_str="%s",timestamp="%s",serial_no="%s",signature="%s"`,
+		c.mchId, nonceStr, timestamp, c.serialNo, signature), nil
+}
+
+// sign 按照 method\nurlPath\ntimestamp\nnonce\nbody\n 的格式构造待签名串并签名, 返回 base64 编码的签名.
+func (c *rsaCredential) sign(method, urlPath, timestamp, nonceStr string, body []byte) (string, error) {
+	message := method + "\n" + urlPath + "\n" + timestamp + "\n" + nonceStr + "\n" + string(body) + "\n"
+
+	hashed := sha256.Sum256([]byte(message))
+
+	signatureBytes, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", fmt.Errorf("apiv3: 签名失败: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(signatureBytes), nil
+}
@@ -0,0 +1,49 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+// DecryptAESGCM 用 apiv3Key 以 AES-256-GCM 解密微信支付下发的敏感数据(平台证书, 回调通知的 resource 等),
+// nonce, associatedData, ciphertext 均为对应字段的原始值(ciphertext 为 base64 编码). 导出给 notify 子包复用.
+func DecryptAESGCM(apiv3Key, nonce, associatedData, ciphertext string) ([]byte, error) {
+	block, err := aes.NewCipher([]byte(apiv3Key))
+	if err != nil {
+		return nil, fmt.Errorf("无效的 APIv3 key: %s", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("ciphertext base64 解码失败: %s", err)
+	}
+
+	plaintext, err := gcm.Open(nil, []byte(nonce), data, []byte(associatedData))
+	if err != nil {
+		return nil, fmt.Errorf("AES-256-GCM 解密失败: %s", err)
+	}
+	return plaintext, nil
+}
+
+// parseCertificatePEM 解析 PEM 格式的 X.509 证书.
+func parseCertificatePEM(pemBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("pem 解码失败")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
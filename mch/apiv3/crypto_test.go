@@ -0,0 +1,67 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"testing"
+)
+
+func encryptAESGCM(t *testing.T, apiv3Key, nonce, associatedData string, plaintext []byte) string {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte(apiv3Key))
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("cipher.NewGCM: %v", err)
+	}
+	ciphertext := gcm.Seal(nil, []byte(nonce), plaintext, []byte(associatedData))
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestDecryptAESGCM(t *testing.T) {
+	const (
+		apiv3Key       = "0123456789abcdef0123456789abcdef" // 32 字节
+		nonce          = "abcdef123456"
+		associatedData = "certificate"
+	)
+	plaintext := []byte(`{"serial_no":"123456"}`)
+
+	ciphertext := encryptAESGCM(t, apiv3Key, nonce, associatedData, plaintext)
+
+	got, err := DecryptAESGCM(apiv3Key, nonce, associatedData, ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptAESGCM: %v", err)
+	}
+	if string(got) != string(plaintext) {
+		t.Fatalf("plaintext mismatch, have %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecryptAESGCM_WrongKey(t *testing.T) {
+	const (
+		apiv3Key       = "0123456789abcdef0123456789abcdef"
+		wrongKey       = "fedcba9876543210fedcba9876543210"
+		nonce          = "abcdef123456"
+		associatedData = "certificate"
+	)
+	ciphertext := encryptAESGCM(t, apiv3Key, nonce, associatedData, []byte("hello"))
+
+	if _, err := DecryptAESGCM(wrongKey, nonce, associatedData, ciphertext); err == nil {
+		t.Fatal("expected error when decrypting with the wrong key, got nil")
+	}
+}
+
+func TestDecryptAESGCM_InvalidBase64(t *testing.T) {
+	if _, err := DecryptAESGCM("0123456789abcdef0123456789abcdef", "nonce", "aad", "not-base64!"); err == nil {
+		t.Fatal("expected error for invalid base64 ciphertext, got nil")
+	}
+}
@@ -0,0 +1,47 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+)
+
+// LoadPrivateKeyWithPath 从 path 指定的 PEM 文件加载商户 API 证书的 PKCS#8 私钥(apiclient_key.pem).
+func LoadPrivateKeyWithPath(path string) (*rsa.PrivateKey, error) {
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("apiv3: %q pem 解码失败", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("apiv3: 解析私钥(%q)失败: %s", path, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("apiv3: %q 不是 RSA 私钥", path)
+	}
+	return rsaKey, nil
+}
+
+// LoadCertificateWithPath 从 path 指定的 PEM 文件加载一张 X.509 证书(商户 API 证书或微信支付平台证书).
+func LoadCertificateWithPath(path string) (*x509.Certificate, error) {
+	pemBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificatePEM(pemBytes)
+}
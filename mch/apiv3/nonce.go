@@ -0,0 +1,20 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// randomNonceStr 生成一个长度为 n 的随机十六进制字符串, 用作请求签名的 nonce_str.
+func randomNonceStr(n int) (string, error) {
+	b := make([]byte, (n+1)/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b)[:n], nil
+}
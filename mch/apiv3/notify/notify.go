@@ -0,0 +1,111 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+// Package notify 实现微信支付 APIv3 回调通知(如支付结果通知)的验签和解密.
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/chanxuehong/wechat/mch/apiv3"
+)
+
+// Resource 对应回调通知 body 里的 resource 字段, 承载被加密的业务数据.
+type Resource struct {
+	OriginalType   string `json:"original_type"`
+	Algorithm      string `json:"algorithm"`
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData string `json:"associated_data"`
+	Nonce          string `json:"nonce"`
+}
+
+// Request 对应微信支付 APIv3 回调通知的 body.
+type Request struct {
+	Id           string   `json:"id"`
+	CreateTime   string   `json:"create_time"`
+	EventType    string   `json:"event_type"`
+	ResourceType string   `json:"resource_type"`
+	Summary      string   `json:"summary"`
+	Resource     Resource `json:"resource"`
+}
+
+// Handler 验证并解密微信支付 APIv3 的回调通知.
+//  apiv3Key: 用于解密 resource.ciphertext 的 APIv3 密钥
+type Handler struct {
+	validator apiv3.Validator
+	apiv3Key  string
+}
+
+// NewHandler 创建一个 Handler.
+func NewHandler(validator apiv3.Validator, apiv3Key string) *Handler {
+	return &Handler{validator: validator, apiv3Key: apiv3Key}
+}
+
+// Parse 验证 http.Request 的签名, 解析出 Request, 并解密其中的 resource.ciphertext.
+// plaintext 是解密后的业务数据 json, 由调用者按照 event_type 自行反序列化.
+func (h *Handler) Parse(r *http.Request) (req *Request, plaintext []byte, err error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNo := r.Header.Get("Wechatpay-Serial")
+	timestamp := r.Header.Get("Wechatpay-Timestamp")
+	nonceStr := r.Header.Get("Wechatpay-Nonce")
+	signature := r.Header.Get("Wechatpay-Signature")
+
+	if err := h.validator.Validate(serialNo, timestamp, nonceStr, body, signature); err != nil {
+		return nil, nil, fmt.Errorf("notify: 验证回调签名失败: %s", err)
+	}
+
+	req = &Request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, nil, fmt.Errorf("notify: 解析回调 body 失败: %s", err)
+	}
+
+	plaintext, err = h.decrypt(&req.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, plaintext, nil
+}
+
+func (h *Handler) decrypt(resource *Resource) ([]byte, error) {
+	plaintext, err := apiv3.DecryptAESGCM(h.apiv3Key, resource.Nonce, resource.AssociatedData, resource.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("notify: %s", err)
+	}
+	return plaintext, nil
+}
+
+// Response 是收到回调通知后应该返回给微信支付服务器的应答 body.
+type Response struct {
+	Code    string `json:"code"`    // "SUCCESS" 表示成功, 其它表示失败
+	Message string `json:"message"` // 失败时的错误信息
+}
+
+// WriteSuccess 向 w 写入处理成功的应答.
+func WriteSuccess(w http.ResponseWriter) {
+	writeResponse(w, http.StatusOK, &Response{Code: "SUCCESS"})
+}
+
+// WriteFail 向 w 写入处理失败的应答, message 会被微信支付记录下来, 便于排查.
+func WriteFail(w http.ResponseWriter, message string) {
+	writeResponse(w, http.StatusInternalServerError, &Response{Code: "FAIL", Message: message})
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, resp *Response) {
+	body, err := json.Marshal(resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
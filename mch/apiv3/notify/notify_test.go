@@ -0,0 +1,155 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package notify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanxuehong/wechat/mch/apiv3"
+)
+
+const testApiv3Key = "0123456789abcdef0123456789abcdef" // 32 字节, 仅用于测试
+
+func selfSignedCert(t *testing.T, priv *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test platform cert"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate: %v", err)
+	}
+	return cert
+}
+
+func encryptAESGCM(t *testing.T, apiv3Key, nonce, associatedData string, plaintext []byte) string {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte(apiv3Key))
+	if err != nil {
+		t.Fatalf("aes.NewCipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("cipher.NewGCM: %v", err)
+	}
+	ciphertext := gcm.Seal(nil, []byte(nonce), plaintext, []byte(associatedData))
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func newSignedNotifyRequest(t *testing.T, priv *rsa.PrivateKey, serialNo string, body []byte, tamper bool) *http.Request {
+	t.Helper()
+
+	const (
+		timestamp = "1600000000"
+		nonceStr  = "reqnonce"
+	)
+
+	message := timestamp + "\n" + nonceStr + "\n" + string(body) + "\n"
+	hashed := sha256.Sum256([]byte(message))
+	sigBytes, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("rsa.SignPKCS1v15: %v", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(sigBytes)
+	if tamper {
+		signature = signature[:len(signature)-4] + "abcd"
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/notify", bytes.NewReader(body))
+	req.Header.Set("Wechatpay-Serial", serialNo)
+	req.Header.Set("Wechatpay-Timestamp", timestamp)
+	req.Header.Set("Wechatpay-Nonce", nonceStr)
+	req.Header.Set("Wechatpay-Signature", signature)
+	return req
+}
+
+func TestHandler_Parse(t *testing.T) {
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	cert := selfSignedCert(t, platformKey)
+
+	certs := apiv3.NewCertificateStore()
+	certs.ReplaceAll(map[string]*x509.Certificate{"platform-serial": cert})
+	validator := apiv3.NewValidator(certs)
+
+	const (
+		resourceNonce = "resnonce123!" // GCM 要求 12 字节
+		resourceAAD   = "transaction"
+	)
+	plaintext := []byte(`{"transaction_id":"123456"}`)
+	ciphertext := encryptAESGCM(t, testApiv3Key, resourceNonce, resourceAAD, plaintext)
+
+	body := []byte(fmt.Sprintf(`{
+		"id": "evt-1",
+		"event_type": "TRANSACTION.SUCCESS",
+		"resource": {
+			"algorithm": "AEAD_AES_256_GCM",
+			"ciphertext": %q,
+			"associated_data": %q,
+			"nonce": %q
+		}
+	}`, ciphertext, resourceAAD, resourceNonce))
+
+	h := NewHandler(validator, testApiv3Key)
+
+	req := newSignedNotifyRequest(t, platformKey, "platform-serial", body, false)
+	parsed, gotPlaintext, err := h.Parse(req)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.Id != "evt-1" {
+		t.Fatalf("Id = %q, want %q", parsed.Id, "evt-1")
+	}
+	if string(gotPlaintext) != string(plaintext) {
+		t.Fatalf("plaintext = %q, want %q", gotPlaintext, plaintext)
+	}
+}
+
+func TestHandler_Parse_RejectsTamperedSignature(t *testing.T) {
+	platformKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	cert := selfSignedCert(t, platformKey)
+
+	certs := apiv3.NewCertificateStore()
+	certs.ReplaceAll(map[string]*x509.Certificate{"platform-serial": cert})
+	validator := apiv3.NewValidator(certs)
+
+	body := []byte(`{"id":"evt-2","resource":{}}`)
+	h := NewHandler(validator, testApiv3Key)
+
+	req := newSignedNotifyRequest(t, platformKey, "platform-serial", body, true)
+	if _, _, err := h.Parse(req); err == nil {
+		t.Fatal("expected error for tampered signature, got nil")
+	}
+}
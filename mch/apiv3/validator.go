@@ -0,0 +1,60 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// Validator 用于验证微信支付服务器应答(或回调通知)的签名.
+type Validator interface {
+	// Validate 验证签名, 正确时返回 nil, 否则返回错误信息.
+	//  serialNo:  应答头 Wechatpay-Serial 的值, 用于查找对应的平台证书
+	//  timestamp: 应答头 Wechatpay-Timestamp 的值
+	//  nonceStr:  应答头 Wechatpay-Nonce 的值
+	//  body:      应答的 body
+	//  signature: 应答头 Wechatpay-Signature 的值
+	Validate(serialNo, timestamp, nonceStr string, body []byte, signature string) error
+}
+
+// rsaValidator 是 Validator 基于微信支付平台证书(RSA)的默认实现.
+type rsaValidator struct {
+	certs *CertificateStore
+}
+
+// NewValidator 创建一个基于平台证书仓库的 Validator.
+func NewValidator(certs *CertificateStore) Validator {
+	return &rsaValidator{certs: certs}
+}
+
+func (v *rsaValidator) Validate(serialNo, timestamp, nonceStr string, body []byte, signature string) error {
+	cert := v.certs.Certificate(serialNo)
+	if cert == nil {
+		return fmt.Errorf("apiv3: 未知的平台证书序列号: %q", serialNo)
+	}
+
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("apiv3: 平台证书(serial_no=%q)的公钥类型不正确", serialNo)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("apiv3: 签名 base64 解码失败: %s", err)
+	}
+
+	message := timestamp + "\n" + nonceStr + "\n" + string(body) + "\n"
+	hashed := sha256.Sum256([]byte(message))
+
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hashed[:], sig); err != nil {
+		return fmt.Errorf("apiv3: 验证签名失败: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package apiv3
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// selfSignedCert 生成一张用 priv 对应公钥自签名的测试证书, 仅用于单元测试.
+func selfSignedCert(t *testing.T, priv *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test platform cert"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate: %v", err)
+	}
+	return cert
+}
+
+func TestRsaValidator_Validate(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	cert := selfSignedCert(t, priv)
+
+	certs := NewCertificateStore()
+	certs.ReplaceAll(map[string]*x509.Certificate{"serial-1": cert})
+	validator := NewValidator(certs)
+
+	const (
+		serialNo  = "serial-1"
+		timestamp = "1600000000"
+		nonceStr  = "nonce123"
+	)
+	body := []byte(`{"code":"SUCCESS"}`)
+
+	message := timestamp + "\n" + nonceStr + "\n" + string(body) + "\n"
+	hashed := sha256.Sum256([]byte(message))
+	sigBytes, err := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("rsa.SignPKCS1v15: %v", err)
+	}
+	signature := base64.StdEncoding.EncodeToString(sigBytes)
+
+	if err := validator.Validate(serialNo, timestamp, nonceStr, body, signature); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	if err := validator.Validate(serialNo, timestamp, nonceStr, []byte(`{"code":"TAMPERED"}`), signature); err == nil {
+		t.Fatal("expected error for tampered body, got nil")
+	}
+
+	if err := validator.Validate("unknown-serial", timestamp, nonceStr, body, signature); err == nil {
+		t.Fatal("expected error for unknown serial_no, got nil")
+	}
+}
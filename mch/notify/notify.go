@@ -0,0 +1,143 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+// Package notify 实现微信支付(native, js 等)推送到 notify_url 的支付结果通知的通用处理流程:
+// 验签, 幂等去重, 业务分发, 以及按约定写回 XML 应答. PayNotifyRequest(实现 Request 接口) 和
+// NewPayNotifyHandler 对这些接口是通用的, 各支付接口只需要把类型和构造函数 alias 过去即可,
+// 不需要重新定义字段或者重复实现 http.Handler 的分发逻辑(see mp/pay2/native, mp/pay3/js).
+package notify
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+)
+
+// Request 由各支付接口的 PayNotifyRequest 实现, 供 Handler 统一处理.
+type Request interface {
+	// Communicated 返回 return_code 是否为 "SUCCESS", 这个字段只代表通信是否成功, 不代表交易结果.
+	Communicated() bool
+	// CheckSignature 验证通知的签名是否正确.
+	CheckSignature(appKey string) error
+	// DedupeKey 返回用于幂等去重的标识, 通常是 transaction_id.
+	DedupeKey() string
+}
+
+// DeduplicateStore 用于支付结果通知的幂等处理, 调用方按需实现(比如基于 redis SETNX 或数据库唯一索引),
+// 防止微信支付因为没有及时收到应答而重复推送同一笔通知时, 业务逻辑被重复执行.
+//
+// IsDone 只应该在 handle 成功执行之后才返回 true: 如果在调用 handle 之前就把 key 标记为已处理,
+// 一旦 handle 失败, 微信重试推送的同一笔通知会被误判为"已处理"而直接回复 SUCCESS, 业务逻辑就再也不会
+// 被执行了, 参见 MarkDone.
+type DeduplicateStore interface {
+	// IsDone 返回 key 对应的通知是否已经被成功处理过.
+	IsDone(key string) (bool, error)
+	// MarkDone 在 key 对应的通知被成功处理之后调用, 记录下来防止重复处理.
+	MarkDone(key string) error
+}
+
+// HandleFunc 是处理一条已经验签通过的支付结果通知的业务逻辑, 返回 non-nil error 时会向微信支付回复 FAIL.
+type HandleFunc func(ctx context.Context, req Request) error
+
+// Handler 处理微信支付推送到 notify_url 的支付结果通知, 实现了 http.Handler, 可以直接注册为路由.
+type Handler struct {
+	appKey     string
+	dedup      DeduplicateStore // 可以为 nil, 表示不做去重
+	newRequest func() Request   // 创建一个空的 Request 实例, 供 xml.Unmarshal 填充
+	handle     HandleFunc
+}
+
+// NewHandler 创建一个 Handler.
+//  appKey:     用于验证通知签名的密钥
+//  dedup:      幂等去重存储, 不需要去重时传 nil
+//  newRequest: 创建一个空的 Request 实例(通常是 func() notify.Request { return new(XxxPayNotifyRequest) })
+//  handle:     收到经过验签的支付结果通知后的业务处理逻辑
+func NewHandler(appKey string, dedup DeduplicateStore, newRequest func() Request, handle HandleFunc) *Handler {
+	return &Handler{
+		appKey:     appKey,
+		dedup:      dedup,
+		newRequest: newRequest,
+		handle:     handle,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		WriteFail(w, err.Error())
+		return
+	}
+
+	req := h.newRequest()
+	if err := xml.Unmarshal(body, req); err != nil {
+		WriteFail(w, "invalid xml body")
+		return
+	}
+
+	if !req.Communicated() {
+		// 通信层面失败, 没有交易数据, 无需验签和去重, 回复 SUCCESS 避免微信重复推送.
+		WriteSuccess(w)
+		return
+	}
+
+	if err := req.CheckSignature(h.appKey); err != nil {
+		WriteFail(w, err.Error())
+		return
+	}
+
+	if h.dedup != nil {
+		done, err := h.dedup.IsDone(req.DedupeKey())
+		if err != nil {
+			WriteFail(w, err.Error())
+			return
+		}
+		if done {
+			// 同一笔通知已经处理成功过, 直接回复 SUCCESS, 不再重复调用业务逻辑.
+			WriteSuccess(w)
+			return
+		}
+	}
+
+	if err := h.handle(r.Context(), req); err != nil {
+		WriteFail(w, err.Error())
+		return
+	}
+
+	if h.dedup != nil {
+		// 只有 handle 成功之后才标记为已处理; handle 失败时不标记, 微信重试推送会重新走一次业务逻辑.
+		if err := h.dedup.MarkDone(req.DedupeKey()); err != nil {
+			WriteFail(w, err.Error())
+			return
+		}
+	}
+	WriteSuccess(w)
+}
+
+type reply struct {
+	XMLName    struct{} `xml:"xml"`
+	ReturnCode string   `xml:"return_code"`
+	ReturnMsg  string   `xml:"return_msg"`
+}
+
+// WriteSuccess 向 w 写入处理成功的 XML 应答.
+func WriteSuccess(w http.ResponseWriter) {
+	writeReply(w, "SUCCESS", "OK")
+}
+
+// WriteFail 向 w 写入处理失败的 XML 应答, message 会被微信支付记录下来, 便于排查.
+func WriteFail(w http.ResponseWriter, message string) {
+	writeReply(w, "FAIL", message)
+}
+
+func writeReply(w http.ResponseWriter, returnCode, returnMsg string) {
+	body, err := xml.Marshal(&reply{ReturnCode: returnCode, ReturnMsg: returnMsg})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(body)
+}
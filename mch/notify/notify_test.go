@@ -0,0 +1,203 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRequest 是测试用的最简 Request 实现.
+type fakeRequest struct {
+	XMLName    struct{} `xml:"xml"`
+	ReturnCode string   `xml:"return_code"`
+	Id         string   `xml:"id"`
+}
+
+func (r *fakeRequest) Communicated() bool { return r.ReturnCode == "SUCCESS" }
+
+func (r *fakeRequest) CheckSignature(appKey string) error {
+	if appKey != "validkey" {
+		return errors.New("notify: 签名校验失败")
+	}
+	return nil
+}
+
+func (r *fakeRequest) DedupeKey() string { return r.Id }
+
+// fakeDedupStore 是测试用的内存版 DeduplicateStore.
+type fakeDedupStore struct {
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func newFakeDedupStore() *fakeDedupStore {
+	return &fakeDedupStore{done: make(map[string]bool)}
+}
+
+func (s *fakeDedupStore) IsDone(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done[key], nil
+}
+
+func (s *fakeDedupStore) MarkDone(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[key] = true
+	return nil
+}
+
+func doNotify(h *Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_ServeHTTP_Success(t *testing.T) {
+	dedup := newFakeDedupStore()
+	var handleCalls int
+	h := NewHandler("validkey", dedup,
+		func() Request { return new(fakeRequest) },
+		func(ctx context.Context, req Request) error {
+			handleCalls++
+			return nil
+		},
+	)
+
+	body := `<xml><return_code>SUCCESS</return_code><id>order-1</id></xml>`
+	rec := doNotify(h, body)
+
+	if handleCalls != 1 {
+		t.Fatalf("handleCalls = %d, want 1", handleCalls)
+	}
+	if !strings.Contains(rec.Body.String(), "<return_code>SUCCESS</return_code>") {
+		t.Fatalf("unexpected response body: %s", rec.Body.String())
+	}
+	done, err := dedup.IsDone("order-1")
+	if err != nil || !done {
+		t.Fatalf("expected dedup key to be marked done after successful handle, done=%v err=%v", done, err)
+	}
+}
+
+// TestHandler_ServeHTTP_HandleFailure_NotMarkedDone 复现并验证评审指出的问题: handle 失败时不应该
+// 把 key 标记为已处理, 否则微信重试推送同一笔通知时会被误判为已处理, 业务逻辑永远不会再执行.
+func TestHandler_ServeHTTP_HandleFailure_NotMarkedDone(t *testing.T) {
+	dedup := newFakeDedupStore()
+	var handleCalls int
+	h := NewHandler("validkey", dedup,
+		func() Request { return new(fakeRequest) },
+		func(ctx context.Context, req Request) error {
+			handleCalls++
+			if handleCalls == 1 {
+				return fmt.Errorf("temporary failure")
+			}
+			return nil
+		},
+	)
+
+	body := `<xml><return_code>SUCCESS</return_code><id>order-2</id></xml>`
+
+	rec1 := doNotify(h, body)
+	if handleCalls != 1 {
+		t.Fatalf("handleCalls after first attempt = %d, want 1", handleCalls)
+	}
+	if !strings.Contains(rec1.Body.String(), "<return_code>FAIL</return_code>") {
+		t.Fatalf("expected FAIL response after handle error, got: %s", rec1.Body.String())
+	}
+	if done, _ := dedup.IsDone("order-2"); done {
+		t.Fatal("key must not be marked done when handle failed")
+	}
+
+	// 微信支付重试推送同一笔通知.
+	rec2 := doNotify(h, body)
+	if handleCalls != 2 {
+		t.Fatalf("handleCalls after retry = %d, want 2 (handle must run again)", handleCalls)
+	}
+	if !strings.Contains(rec2.Body.String(), "<return_code>SUCCESS</return_code>") {
+		t.Fatalf("expected SUCCESS response after retry succeeds, got: %s", rec2.Body.String())
+	}
+	if done, _ := dedup.IsDone("order-2"); !done {
+		t.Fatal("key must be marked done after the retry succeeds")
+	}
+}
+
+func TestHandler_ServeHTTP_SkipsHandleWhenAlreadyDone(t *testing.T) {
+	dedup := newFakeDedupStore()
+	if err := dedup.MarkDone("order-3"); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+
+	var handleCalls int
+	h := NewHandler("validkey", dedup,
+		func() Request { return new(fakeRequest) },
+		func(ctx context.Context, req Request) error {
+			handleCalls++
+			return nil
+		},
+	)
+
+	body := `<xml><return_code>SUCCESS</return_code><id>order-3</id></xml>`
+	rec := doNotify(h, body)
+
+	if handleCalls != 0 {
+		t.Fatalf("handleCalls = %d, want 0 for an already-processed notification", handleCalls)
+	}
+	if !strings.Contains(rec.Body.String(), "<return_code>SUCCESS</return_code>") {
+		t.Fatalf("unexpected response body: %s", rec.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTP_RejectsBadSignature(t *testing.T) {
+	dedup := newFakeDedupStore()
+	var handleCalls int
+	h := NewHandler("wrongkey", dedup,
+		func() Request { return new(fakeRequest) },
+		func(ctx context.Context, req Request) error {
+			handleCalls++
+			return nil
+		},
+	)
+
+	body := `<xml><return_code>SUCCESS</return_code><id>order-4</id></xml>`
+	rec := doNotify(h, body)
+
+	if handleCalls != 0 {
+		t.Fatalf("handleCalls = %d, want 0 when signature check fails", handleCalls)
+	}
+	if !strings.Contains(rec.Body.String(), "<return_code>FAIL</return_code>") {
+		t.Fatalf("expected FAIL response, got: %s", rec.Body.String())
+	}
+}
+
+func TestHandler_ServeHTTP_NotCommunicated(t *testing.T) {
+	dedup := newFakeDedupStore()
+	var handleCalls int
+	h := NewHandler("validkey", dedup,
+		func() Request { return new(fakeRequest) },
+		func(ctx context.Context, req Request) error {
+			handleCalls++
+			return nil
+		},
+	)
+
+	body := `<xml><return_code>FAIL</return_code><id>order-5</id></xml>`
+	rec := doNotify(h, body)
+
+	if handleCalls != 0 {
+		t.Fatalf("handleCalls = %d, want 0 when Communicated() is false", handleCalls)
+	}
+	if !strings.Contains(rec.Body.String(), "<return_code>SUCCESS</return_code>") {
+		t.Fatalf("expected SUCCESS response for a communication-level failure, got: %s", rec.Body.String())
+	}
+}
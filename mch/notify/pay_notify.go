@@ -0,0 +1,109 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package notify
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/chanxuehong/wechat/mch/sign"
+)
+
+// PayNotifyRequest 是微信支付(native, JSAPI 等下单类接口)支付完成后推送到 notify_url 的支付结果通知的
+// xml 数据结构, 字段名和微信支付文档一致. 这个结构体在各支付接口之间完全相同, 因此统一放在这里,
+// 各支付接口的 PayNotifyRequest 直接 alias 到这个类型(see mp/pay2/native, mp/pay3/js).
+type PayNotifyRequest struct {
+	XMLName struct{} `xml:"xml" json:"-"`
+
+	ReturnCode string `xml:"return_code" json:"return_code"` // 必须, SUCCESS/FAIL, 此字段是通信标识, 非交易标识
+	ReturnMsg  string `xml:"return_msg"  json:"return_msg"`   // ReturnCode 为 FAIL 时填写错误原因
+
+	AppId    string `xml:"appid"     json:"appid"`
+	MchId    string `xml:"mch_id"    json:"mch_id"`
+	NonceStr string `xml:"nonce_str" json:"nonce_str"`
+	Sign     string `xml:"sign"      json:"sign"`
+	SignType string `xml:"sign_type" json:"sign_type"` // 不填默认为 MD5
+
+	ResultCode string `xml:"result_code"  json:"result_code"` // ReturnCode 为 SUCCESS 时才有意义, SUCCESS/FAIL
+	ErrCode    string `xml:"err_code"     json:"err_code"`
+	ErrCodeDes string `xml:"err_code_des" json:"err_code_des"`
+
+	OpenId      string `xml:"openid"       json:"openid"`
+	IsSubscribe string `xml:"is_subscribe" json:"is_subscribe"`
+	TradeType   string `xml:"trade_type"   json:"trade_type"`
+	BankType    string `xml:"bank_type"    json:"bank_type"`
+	TotalFee    int64  `xml:"total_fee"    json:"total_fee"`
+	FeeType     string `xml:"fee_type"     json:"fee_type"`
+
+	TransactionId string `xml:"transaction_id" json:"transaction_id"` // 微信支付订单号
+	OutTradeNo    string `xml:"out_trade_no"    json:"out_trade_no"`  // 商户订单号
+	Attach        string `xml:"attach"          json:"attach"`
+	TimeEnd       string `xml:"time_end"        json:"time_end"` // 支付完成时间, yyyyMMddHHmmss
+}
+
+// SignFields 把参与签名的字段整理成 sign.Sign/sign.Verify 需要的 map.
+//  appKey: 商户密钥 Key; MD5 方式下作为 "appkey" 字段参与拼接, HMAC-SHA256 方式下不出现在这个 map 里
+func (req *PayNotifyRequest) SignFields(appKey string) map[string]string {
+	fields := map[string]string{
+		"appid":          req.AppId,
+		"bank_type":      req.BankType,
+		"err_code":       req.ErrCode,
+		"err_code_des":   req.ErrCodeDes,
+		"fee_type":       req.FeeType,
+		"is_subscribe":   req.IsSubscribe,
+		"mch_id":         req.MchId,
+		"nonce_str":      req.NonceStr,
+		"openid":         req.OpenId,
+		"out_trade_no":   req.OutTradeNo,
+		"attach":         req.Attach,
+		"result_code":    req.ResultCode,
+		"time_end":       req.TimeEnd,
+		"total_fee":      strconv.FormatInt(req.TotalFee, 10),
+		"trade_type":     req.TradeType,
+		"transaction_id": req.TransactionId,
+	}
+	switch req.SignType {
+	case "", "md5", "MD5":
+		fields["appkey"] = appKey
+	}
+	return fields
+}
+
+// CheckSignature 检查 req 的签名是否正确, 正确时返回 nil, 否则返回错误信息.
+//  appKey: 商户密钥 Key
+func (req *PayNotifyRequest) CheckSignature(appKey string) error {
+	signType := req.SignType
+	if signType == "" {
+		signType = sign.MethodMD5
+	}
+	return sign.Verify(req.SignFields(appKey), appKey, signType, req.Sign)
+}
+
+// Communicated 实现 Request, 返回 return_code 是否为 "SUCCESS".
+func (req *PayNotifyRequest) Communicated() bool {
+	return req.ReturnCode == "SUCCESS"
+}
+
+// DedupeKey 实现 Request, 用微信支付订单号作为幂等去重的标识.
+func (req *PayNotifyRequest) DedupeKey() string {
+	return req.TransactionId
+}
+
+// PayNotifyHandleFunc 是处理一条已经验签通过的支付结果通知的业务逻辑, 返回 non-nil error 时会向微信支付回复 FAIL.
+type PayNotifyHandleFunc func(ctx context.Context, req *PayNotifyRequest) error
+
+// NewPayNotifyHandler 创建一个处理支付结果通知的 Handler, 可以直接注册为 notify_url 对应的路由.
+//  appKey: 商户密钥 Key, 用于验证通知的签名
+//  dedup:  幂等去重存储, 不需要去重时传 nil
+//  handle: 收到经过验签的支付结果通知后的业务处理逻辑
+func NewPayNotifyHandler(appKey string, dedup DeduplicateStore, handle PayNotifyHandleFunc) *Handler {
+	return NewHandler(appKey, dedup,
+		func() Request { return new(PayNotifyRequest) },
+		func(ctx context.Context, req Request) error {
+			return handle(ctx, req.(*PayNotifyRequest))
+		},
+	)
+}
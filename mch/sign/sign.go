@@ -0,0 +1,101 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+// Package sign 提供一个通用的签名/验签助手, 取代各个支付接口手写的字段拼接代码.
+//
+// 使用方式: 调用方把参与签名的字段整理成 map[string]string(空值字段会被自动跳过),
+// 按自己接口约定决定密钥字段(如 "appkey")是否作为普通字段放进这个 map, 再调用 Sign/Verify.
+// 对于摘要类算法(MD5, SHA1), key 只在调用方已经把密钥放进 map 时才会参与签名, Sign/Verify 本身不会
+// 额外拼接 key; 对于 HMAC-SHA256, key 则直接作为 hmac 的密钥使用, 不会出现在待签名串中.
+package sign
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+)
+
+// 支持的签名方式.
+const (
+	MethodMD5        = "MD5"
+	MethodSHA1       = "SHA1"
+	MethodHMACSHA256 = "HMAC-SHA256"
+)
+
+// Sign 对 params 中的非空字段按字典序拼接为 k1=v1&k2=v2&... 并用 signType 指定的算法签名.
+//  params:   参与签名的字段, 空值字段会被自动跳过
+//  key:      HMAC-SHA256 方式下作为 hmac 密钥使用; 其它方式下被忽略(密钥应已由调用方放进 params)
+//  signType: "MD5", "SHA1" 或 "HMAC-SHA256", 也接受全小写写法("md5", "sha1", "hmac-sha256"), 其它大小写组合视为未知签名方式
+func Sign(params map[string]string, key string, signType string) (string, error) {
+	Hash, upper, err := newHash(key, signType)
+	if err != nil {
+		return "", err
+	}
+
+	writeParams(Hash, params)
+
+	signature := hex.EncodeToString(Hash.Sum(nil))
+	if upper {
+		signature = strings.ToUpper(signature)
+	}
+	return signature, nil
+}
+
+// Verify 验证 params 在给定 key, signType 下的签名是否等于 signature.
+func Verify(params map[string]string, key string, signType string, signature string) error {
+	want, err := Sign(params, key, signType)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(want), []byte(signature)) != 1 {
+		return fmt.Errorf("sign: 不正确的签名, \r\nhave: %q, \r\nwant: %q", signature, want)
+	}
+	return nil
+}
+
+// newHash 按 signType 返回对应的 hash.Hash, 以及签名最终是否需要转成大写十六进制.
+func newHash(key, signType string) (h hash.Hash, upper bool, err error) {
+	switch signType {
+	case "md5", "MD5":
+		return md5.New(), true, nil
+	case "sha1", "SHA1":
+		return sha1.New(), false, nil
+	case "hmac-sha256", "HMAC-SHA256":
+		return hmac.New(sha256.New, []byte(key)), true, nil
+	default:
+		return nil, false, fmt.Errorf("sign: unknown sign method: %q", signType)
+	}
+}
+
+// writeParams 把 params 中的非空字段按字典序写入 Hash, 形如 k1=v1&k2=v2&...
+func writeParams(Hash hash.Hash, params map[string]string) {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if v == "" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		buf.WriteString(params[k])
+	}
+	Hash.Write(buf.Bytes())
+}
@@ -0,0 +1,113 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSign_MD5(t *testing.T) {
+	params := map[string]string{
+		"appid":    "wxappid",
+		"noncestr": "nonce123",
+		"appkey":   "testkey",
+		"empty":    "", // 空值字段应被跳过
+	}
+
+	have, err := Sign(params, "testkey", MethodMD5)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	h := md5.New()
+	h.Write([]byte("appid=wxappid&appkey=testkey&noncestr=nonce123"))
+	want := strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+
+	if have != want {
+		t.Fatalf("have %q, want %q", have, want)
+	}
+}
+
+func TestSign_SHA1(t *testing.T) {
+	params := map[string]string{
+		"appid":    "wxappid",
+		"noncestr": "nonce123",
+		"appkey":   "testkey",
+	}
+
+	have, err := Sign(params, "testkey", MethodSHA1)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	h := sha1.New()
+	h.Write([]byte("appid=wxappid&appkey=testkey&noncestr=nonce123"))
+	want := hex.EncodeToString(h.Sum(nil))
+
+	if have != want {
+		t.Fatalf("have %q, want %q", have, want)
+	}
+}
+
+func TestSign_HMACSHA256(t *testing.T) {
+	params := map[string]string{
+		"appid":    "wxappid",
+		"noncestr": "nonce123",
+	}
+	key := "testkey"
+
+	have, err := Sign(params, key, MethodHMACSHA256)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("appid=wxappid&noncestr=nonce123"))
+	want := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	if have != want {
+		t.Fatalf("have %q, want %q", have, want)
+	}
+}
+
+func TestSign_UnknownMethod(t *testing.T) {
+	if _, err := Sign(map[string]string{"a": "b"}, "key", "SHA256"); err == nil {
+		t.Fatal("expected error for unknown sign method, got nil")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	params := map[string]string{
+		"appid":    "wxappid",
+		"noncestr": "nonce123",
+	}
+	key := "testkey"
+
+	signature, err := Sign(params, key, MethodHMACSHA256)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := Verify(params, key, MethodHMACSHA256, signature); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := Verify(params, key, MethodHMACSHA256, signature+"x"); err == nil {
+		t.Fatal("expected error for mismatched signature, got nil")
+	}
+}
+
+func TestVerify_UnknownMethod(t *testing.T) {
+	if err := Verify(map[string]string{"a": "b"}, "key", "SHA256", "whatever"); err == nil {
+		t.Fatal("expected error for unknown sign method, got nil")
+	}
+}
@@ -6,12 +6,9 @@
 package native
 
 import (
-	"crypto/sha1"
-	"crypto/subtle"
-	"encoding/hex"
-	"fmt"
-	"hash"
 	"strconv"
+
+	"github.com/chanxuehong/wechat/mch/sign"
 )
 
 // 公众平台接到用户点击 Native 支付 URL 之后, 会调用注册时填写的商户获取订单 Package 的回调 URL.
@@ -29,61 +26,31 @@ type PayPackageRequest struct {
 	ProductId string `xml:"ProductId" json:"ProductId"` // 第三方的商品ID 号
 
 	Signature  string `xml:"AppSignature" json:"AppSignature"` // 参数的加密签名
-	SignMethod string `xml:"SignMethod"   json:"SignMethod"`   // 签名方式，目前只支持“SHA1”，该字段不参与签名
+	SignMethod string `xml:"SignMethod"   json:"SignMethod"`   // 签名方式，目前支持 "SHA1", "HMAC-SHA256"，该字段不参与签名
 }
 
-// 检查 req *PayPackageRequest 的签名是否正确, 正确时返回 nil, 否则返回错误信息.
-//  appKey: 即 paySignKey, 公众号支付请求中用于加密的密钥 Key
-func (req *PayPackageRequest) CheckSignature(appKey string) (err error) {
-	var Hash hash.Hash
-	var Signature []byte
-
+// SignFields 把参与签名的字段整理成 sign.Sign/sign.Verify 需要的 map.
+//  appKey: 即 paySignKey; SHA1 方式下作为 "appkey" 字段参与拼接, HMAC-SHA256 方式下不出现在这个 map 里
+func (req *PayPackageRequest) SignFields(appKey string) map[string]string {
+	fields := map[string]string{
+		"appid":       req.AppId,
+		"issubscribe": strconv.FormatInt(int64(req.IsSubscribe), 10),
+		"noncestr":    req.NonceStr,
+		"openid":      req.OpenId,
+		"productid":   req.ProductId,
+		"timestamp":   strconv.FormatInt(req.TimeStamp, 10),
+	}
 	switch req.SignMethod {
 	case "sha1", "SHA1":
-		if len(req.Signature) != sha1.Size*2 {
-			err = fmt.Errorf(`不正确的签名: %q, 长度不对, have: %d, want: %d`,
-				req.Signature, len(req.Signature), sha1.Size*2)
-			return
-		}
-
-		Hash = sha1.New()
-		Signature = make([]byte, sha1.Size*2)
-
-	default:
-		err = fmt.Errorf(`unknown sign method: %q`, req.SignMethod)
-		return
+		fields["appkey"] = appKey
 	}
+	return fields
+}
 
-	// 字典序
-	// appid
-	// appkey
-	// issubscribe
-	// noncestr
-	// openid
-	// productid
-	// timestamp
-	Hash.Write([]byte("appid="))
-	Hash.Write([]byte(req.AppId))
-	Hash.Write([]byte("&appkey="))
-	Hash.Write([]byte(appKey))
-	Hash.Write([]byte("&issubscribe="))
-	Hash.Write([]byte(strconv.FormatInt(int64(req.IsSubscribe), 10)))
-	Hash.Write([]byte("&noncestr="))
-	Hash.Write([]byte(req.NonceStr))
-	Hash.Write([]byte("&openid="))
-	Hash.Write([]byte(req.OpenId))
-	Hash.Write([]byte("&productid="))
-	Hash.Write([]byte(req.ProductId))
-	Hash.Write([]byte("&timestamp="))
-	Hash.Write([]byte(strconv.FormatInt(req.TimeStamp, 10)))
-
-	hex.Encode(Signature, Hash.Sum(nil))
-
-	if subtle.ConstantTimeCompare(Signature, []byte(req.Signature)) != 1 {
-		err = fmt.Errorf("不正确的签名, \r\nhave: %q, \r\nwant: %q", Signature, req.Signature)
-		return
-	}
-	return
+// 检查 req *PayPackageRequest 的签名是否正确, 正确时返回 nil, 否则返回错误信息.
+//  appKey: 即 paySignKey, 公众号支付请求中用于加密的密钥 Key, HMAC-SHA256 方式下作为 hmac 的 key 使用
+func (req *PayPackageRequest) CheckSignature(appKey string) (err error) {
+	return sign.Verify(req.SignFields(appKey), appKey, req.SignMethod, req.Signature)
 }
 
 // 公众平台接到用户点击 Native 支付 URL 之后, 会调用注册时填写的商户获取订单 Package 的回调 URL.
@@ -102,48 +69,36 @@ type PayPackageResponse struct {
 	RetMsg  string `xml:"RetErrMsg" json:"RetErrMsg"` // 必须, 错误信息, 要求 utf8 编码格式
 
 	Signature  string `xml:"AppSignature" json:"AppSignature"` // 必须, 该 PayPackageResponse 自身的签名. see PayPackageResponse.SetSignature
-	SignMethod string `xml:"SignMethod"   json:"SignMethod"`   // 必须, 签名方式, 目前只支持 "sha1"
+	SignMethod string `xml:"SignMethod"   json:"SignMethod"`   // 必须, 签名方式, 目前支持 "sha1", "HMAC-SHA256"
+}
+
+// SignFields 把参与签名的字段整理成 sign.Sign/sign.Verify 需要的 map.
+//  appKey: 即 paySignKey; SHA1 方式下作为 "appkey" 字段参与拼接, HMAC-SHA256 方式下不出现在这个 map 里
+func (resp *PayPackageResponse) SignFields(appKey string) map[string]string {
+	fields := map[string]string{
+		"appid":     resp.AppId,
+		"noncestr":  resp.NonceStr,
+		"package":   resp.Package,
+		"retcode":   strconv.FormatInt(int64(resp.RetCode), 10),
+		"reterrmsg": resp.RetMsg,
+		"timestamp": strconv.FormatInt(resp.TimeStamp, 10),
+	}
+	switch resp.SignMethod {
+	case "sha1", "SHA1":
+		fields["appkey"] = appKey
+	}
+	return fields
 }
 
 // 设置签名字段.
-//  appKey: 即 paySignKey, 公众号支付请求中用于加密的密钥 Key
+//  appKey: 即 paySignKey, 公众号支付请求中用于加密的密钥 Key, HMAC-SHA256 方式下作为 hmac 的 key 使用
 //
 //  NOTE: 要求在 resp *PayPackageResponse 其他字段设置完毕后才能调用这个函数, 否则签名就不正确.
 func (resp *PayPackageResponse) SetSignature(appKey string) (err error) {
-	var Hash hash.Hash
-
-	switch resp.SignMethod {
-	case "sha1", "SHA1":
-		Hash = sha1.New()
-
-	default:
-		err = fmt.Errorf(`unknown sign method: %q`, resp.SignMethod)
-		return
+	signature, err := sign.Sign(resp.SignFields(appKey), appKey, resp.SignMethod)
+	if err != nil {
+		return err
 	}
-
-	// 字典序
-	// appid
-	// appkey
-	// noncestr
-	// package
-	// retcode
-	// reterrmsg
-	// timestamp
-	Hash.Write([]byte("appid="))
-	Hash.Write([]byte(resp.AppId))
-	Hash.Write([]byte("&appkey="))
-	Hash.Write([]byte(appKey))
-	Hash.Write([]byte("&noncestr="))
-	Hash.Write([]byte(resp.NonceStr))
-	Hash.Write([]byte("&package="))
-	Hash.Write([]byte(resp.Package))
-	Hash.Write([]byte("&retcode="))
-	Hash.Write([]byte(strconv.FormatInt(int64(resp.RetCode), 10)))
-	Hash.Write([]byte("&reterrmsg="))
-	Hash.Write([]byte(resp.RetMsg))
-	Hash.Write([]byte("&timestamp="))
-	Hash.Write([]byte(strconv.FormatInt(resp.TimeStamp, 10)))
-
-	resp.Signature = hex.EncodeToString(Hash.Sum(nil))
-	return
+	resp.Signature = signature
+	return nil
 }
@@ -0,0 +1,104 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package native
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPayPackageRequest_CheckSignature_SHA1(t *testing.T) {
+	req := &PayPackageRequest{
+		AppId:       "wxappid",
+		NonceStr:    "nonce123",
+		TimeStamp:   1400000000,
+		OpenId:      "openid123",
+		IsSubscribe: 1,
+		ProductId:   "product123",
+		SignMethod:  "SHA1",
+	}
+	appKey := "testkey"
+
+	h := sha1.New()
+	h.Write([]byte("appid=" + req.AppId))
+	h.Write([]byte("&appkey=" + appKey))
+	h.Write([]byte("&issubscribe=" + strconv.FormatInt(int64(req.IsSubscribe), 10)))
+	h.Write([]byte("&noncestr=" + req.NonceStr))
+	h.Write([]byte("&openid=" + req.OpenId))
+	h.Write([]byte("&productid=" + req.ProductId))
+	h.Write([]byte("&timestamp=" + strconv.FormatInt(req.TimeStamp, 10)))
+	req.Signature = hex.EncodeToString(h.Sum(nil))
+
+	if err := req.CheckSignature(appKey); err != nil {
+		t.Fatalf("CheckSignature: %v", err)
+	}
+
+	req.Signature += "x"
+	if err := req.CheckSignature(appKey); err == nil {
+		t.Fatal("expected error for tampered signature, got nil")
+	}
+}
+
+func TestPayPackageRequest_CheckSignature_UnknownMethod(t *testing.T) {
+	req := &PayPackageRequest{
+		AppId:      "wxappid",
+		NonceStr:   "nonce123",
+		TimeStamp:  1400000000,
+		ProductId:  "product123",
+		SignMethod: "SHA256",
+		Signature:  "whatever",
+	}
+
+	if err := req.CheckSignature("testkey"); err == nil {
+		t.Fatal("expected error for unknown sign method, got nil")
+	}
+}
+
+func TestPayPackageResponse_SetSignature_HMACSHA256(t *testing.T) {
+	resp := &PayPackageResponse{
+		AppId:      "wxappid",
+		NonceStr:   "nonce123",
+		TimeStamp:  1400000000,
+		Package:    "prepay_id=abc",
+		RetCode:    0,
+		RetMsg:     "OK",
+		SignMethod: "HMAC-SHA256",
+	}
+	appKey := "testkey"
+
+	if err := resp.SetSignature(appKey); err != nil {
+		t.Fatalf("SetSignature: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(appKey))
+	mac.Write([]byte("appid=" + resp.AppId))
+	mac.Write([]byte("&noncestr=" + resp.NonceStr))
+	mac.Write([]byte("&package=" + resp.Package))
+	mac.Write([]byte("&retcode=" + strconv.FormatInt(int64(resp.RetCode), 10)))
+	mac.Write([]byte("&reterrmsg=" + resp.RetMsg))
+	mac.Write([]byte("&timestamp=" + strconv.FormatInt(resp.TimeStamp, 10)))
+	want := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	if resp.Signature != want {
+		t.Fatalf("signature mismatch, have %q, want %q", resp.Signature, want)
+	}
+}
+
+func TestPayPackageResponse_SetSignature_UnknownMethod(t *testing.T) {
+	resp := &PayPackageResponse{
+		AppId:      "wxappid",
+		SignMethod: "SHA256",
+	}
+
+	if err := resp.SetSignature("testkey"); err == nil {
+		t.Fatal("expected error for unknown sign method, got nil")
+	}
+}
@@ -0,0 +1,23 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package js
+
+import "github.com/chanxuehong/wechat/mch/notify"
+
+// JSAPI 支付完成后, 微信支付服务器会向商户配置的 notify_url 发送 POST 请求, 推送支付结果,
+// 这是推送的 xml 格式的数据结构.
+type PayNotifyRequest = notify.PayNotifyRequest
+
+// NotifyHandleFunc 是处理一条已经验签通过的支付结果通知的业务逻辑, 返回 non-nil error 时会向微信支付回复 FAIL.
+type NotifyHandleFunc = notify.PayNotifyHandleFunc
+
+// NewNotifyHandler 创建一个处理 JSAPI 支付结果通知的 notify.Handler, 可以直接注册为 notify_url 对应的路由.
+//  appKey: 商户支付密钥Key, 用于验证通知的签名
+//  dedup:  幂等去重存储, 不需要去重时传 nil
+//  handle: 收到经过验签的支付结果通知后的业务处理逻辑
+func NewNotifyHandler(appKey string, dedup notify.DeduplicateStore, handle NotifyHandleFunc) *notify.Handler {
+	return notify.NewPayNotifyHandler(appKey, dedup, handle)
+}
@@ -6,12 +6,9 @@
 package js
 
 import (
-	"bytes"
-	"crypto/md5"
-	"encoding/hex"
-	"fmt"
-	"hash"
 	"strconv"
+
+	"github.com/chanxuehong/wechat/mch/sign"
 )
 
 // js api 微信支付接口 getBrandWCPayRequest 的参数.
@@ -38,47 +35,34 @@ type PayRequestParameters struct {
 	Package string `json:"package"` // 必须, 订单详情组合成的字符串
 
 	Signature  string `json:"paySign"`  // 必须, 该 PayRequestParameters 自身的签名. see PayRequestParameters.SetSignature
-	SignMethod string `json:"signType"` // 必须, 签名方式, 目前仅支持 MD5
+	SignMethod string `json:"signType"` // 必须, 签名方式, 目前支持 MD5, HMAC-SHA256
+}
+
+// SignFields 把参与签名的字段整理成 sign.Sign/sign.Verify 需要的 map.
+//  appKey: 商户支付密钥Key; MD5 方式下作为 "appkey" 字段参与拼接, HMAC-SHA256 方式下不出现在这个 map 里
+func (para *PayRequestParameters) SignFields(appKey string) map[string]string {
+	fields := map[string]string{
+		"appid":     para.AppId,
+		"noncestr":  para.NonceStr,
+		"package":   para.Package,
+		"timestamp": strconv.FormatInt(para.TimeStamp, 10),
+	}
+	switch para.SignMethod {
+	case "md5", "MD5":
+		fields["appkey"] = appKey
+	}
+	return fields
 }
 
 // 设置签名字段.
-//  appKey: 商户支付密钥Key
+//  appKey: 商户支付密钥Key, 在 HMAC-SHA256 方式下作为 hmac 的 key 使用, 不会拼入待签名串
 //
 //  NOTE: 要求在 para *PayRequestParameters 其他字段设置完毕后才能调用这个函数, 否则签名就不正确.
 func (para *PayRequestParameters) SetSignature(appKey string) (err error) {
-	var Hash hash.Hash
-	var Signature []byte
-
-	switch para.SignMethod {
-	case "md5", "MD5":
-		Hash = md5.New()
-		Signature = make([]byte, md5.Size*2)
-
-	default:
-		err = fmt.Errorf(`unknown sign method: %q`, para.SignMethod)
-		return
+	signature, err := sign.Sign(para.SignFields(appKey), appKey, para.SignMethod)
+	if err != nil {
+		return err
 	}
-
-	// 字典序
-	// appid
-	// appkey
-	// noncestr
-	// package
-	// timestamp
-	Hash.Write([]byte("appid="))
-	Hash.Write([]byte(para.AppId))
-	Hash.Write([]byte("&appkey="))
-	Hash.Write([]byte(appKey))
-	Hash.Write([]byte("&noncestr="))
-	Hash.Write([]byte(para.NonceStr))
-	Hash.Write([]byte("&package="))
-	Hash.Write([]byte(para.Package))
-	Hash.Write([]byte("&timestamp="))
-	Hash.Write([]byte(strconv.FormatInt(para.TimeStamp, 10)))
-
-	hex.Encode(Signature, Hash.Sum(nil))
-	Signature = bytes.ToUpper(Signature)
-
-	para.Signature = string(Signature)
-	return
+	para.Signature = signature
+	return nil
 }
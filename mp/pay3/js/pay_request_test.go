@@ -0,0 +1,83 @@
+// @description wechat 是腾讯微信公众平台 api 的 golang 语言封装
+// @link        https://github.com/chanxuehong/wechat for the canonical source repository
+// @license     https://github.com/chanxuehong/wechat/blob/master/LICENSE
+// @authors     chanxuehong(chanxuehong@gmail.com)
+
+package js
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPayRequestParameters_SetSignature_MD5(t *testing.T) {
+	para := &PayRequestParameters{
+		AppId:      "wxappid",
+		NonceStr:   "nonce123",
+		TimeStamp:  1400000000,
+		Package:    "prepay_id=abc",
+		SignMethod: "MD5",
+	}
+	appKey := "testkey"
+
+	if err := para.SetSignature(appKey); err != nil {
+		t.Fatalf("SetSignature: %v", err)
+	}
+
+	h := md5.New()
+	h.Write([]byte("appid=" + para.AppId))
+	h.Write([]byte("&appkey=" + appKey))
+	h.Write([]byte("&noncestr=" + para.NonceStr))
+	h.Write([]byte("&package=" + para.Package))
+	h.Write([]byte("&timestamp=" + strconv.FormatInt(para.TimeStamp, 10)))
+	want := strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+
+	if para.Signature != want {
+		t.Fatalf("signature mismatch, have %q, want %q", para.Signature, want)
+	}
+}
+
+func TestPayRequestParameters_SetSignature_HMACSHA256(t *testing.T) {
+	para := &PayRequestParameters{
+		AppId:      "wxappid",
+		NonceStr:   "nonce123",
+		TimeStamp:  1400000000,
+		Package:    "prepay_id=abc",
+		SignMethod: "HMAC-SHA256",
+	}
+	appKey := "testkey"
+
+	if err := para.SetSignature(appKey); err != nil {
+		t.Fatalf("SetSignature: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(appKey))
+	mac.Write([]byte("appid=" + para.AppId))
+	mac.Write([]byte("&noncestr=" + para.NonceStr))
+	mac.Write([]byte("&package=" + para.Package))
+	mac.Write([]byte("&timestamp=" + strconv.FormatInt(para.TimeStamp, 10)))
+	want := strings.ToUpper(hex.EncodeToString(mac.Sum(nil)))
+
+	if para.Signature != want {
+		t.Fatalf("signature mismatch, have %q, want %q", para.Signature, want)
+	}
+}
+
+func TestPayRequestParameters_SetSignature_UnknownMethod(t *testing.T) {
+	para := &PayRequestParameters{
+		AppId:      "wxappid",
+		NonceStr:   "nonce123",
+		TimeStamp:  1400000000,
+		Package:    "prepay_id=abc",
+		SignMethod: "SHA256",
+	}
+
+	if err := para.SetSignature("testkey"); err == nil {
+		t.Fatal("expected error for unknown sign method, got nil")
+	}
+}